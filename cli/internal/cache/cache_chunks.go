@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	client2 "github.com/vercel/turbo/cli/internal/client"
+)
+
+// chunkSize is the window used to split an artifact into content-addressed
+// chunks. 4 MiB keeps the chunk count reasonable for typical task outputs
+// while still letting unchanged regions of a tarball dedupe across builds.
+const chunkSize = 4 * 1024 * 1024
+
+// artifactChunk is one fixed-size slice of an artifact body, identified by
+// the sha256 digest of its contents.
+type artifactChunk struct {
+	digest string
+	body   []byte
+}
+
+// artifactManifest is uploaded in place of the raw artifact body once it has
+// been chunked. It records enough to both verify and reassemble the
+// artifact from its chunks; retrieval downloads every listed chunk.
+// It's a type alias for client.ArtifactManifest since that's the type
+// client.APIClient's manifest wire methods actually traffic in.
+type artifactManifest = client2.ArtifactManifest
+
+// splitChunks divides body into fixed-size, content-addressed chunks. A
+// rolling hash (content-defined chunking) would dedupe better across
+// insertions/deletions, but fixed windows are enough to exploit the common
+// case of a task's output being byte-identical to its last run.
+func splitChunks(body []byte) []artifactChunk {
+	if len(body) == 0 {
+		return []artifactChunk{{digest: digestChunk(nil), body: []byte{}}}
+	}
+	chunks := make([]artifactChunk, 0, (len(body)/chunkSize)+1)
+	for offset := 0; offset < len(body); offset += chunkSize {
+		end := offset + chunkSize
+		if end > len(body) {
+			end = len(body)
+		}
+		slice := body[offset:end]
+		chunks = append(chunks, artifactChunk{digest: digestChunk(slice), body: slice})
+	}
+	return chunks
+}
+
+// digestChunk returns the hex-encoded sha256 digest used to address a chunk.
+func digestChunk(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_splitChunks(t *testing.T) {
+	t.Run("empty body produces a single empty chunk", func(t *testing.T) {
+		chunks := splitChunks(nil)
+		if len(chunks) != 1 {
+			t.Fatalf("expected 1 chunk, got %d", len(chunks))
+		}
+		if len(chunks[0].body) != 0 {
+			t.Fatalf("expected empty chunk body, got %d bytes", len(chunks[0].body))
+		}
+		if chunks[0].digest != digestChunk(nil) {
+			t.Fatalf("digest mismatch for empty chunk")
+		}
+	})
+
+	t.Run("body smaller than chunkSize produces one chunk", func(t *testing.T) {
+		body := bytes.Repeat([]byte{'a'}, 100)
+		chunks := splitChunks(body)
+		if len(chunks) != 1 {
+			t.Fatalf("expected 1 chunk, got %d", len(chunks))
+		}
+		if !bytes.Equal(chunks[0].body, body) {
+			t.Fatalf("chunk body doesn't match input")
+		}
+	})
+
+	t.Run("body splits on chunkSize boundaries", func(t *testing.T) {
+		body := bytes.Repeat([]byte{'a'}, chunkSize+1)
+		chunks := splitChunks(body)
+		if len(chunks) != 2 {
+			t.Fatalf("expected 2 chunks, got %d", len(chunks))
+		}
+		if len(chunks[0].body) != chunkSize {
+			t.Fatalf("expected first chunk to be %d bytes, got %d", chunkSize, len(chunks[0].body))
+		}
+		if len(chunks[1].body) != 1 {
+			t.Fatalf("expected second chunk to be 1 byte, got %d", len(chunks[1].body))
+		}
+	})
+
+	t.Run("identical chunks dedupe to identical digests", func(t *testing.T) {
+		body := bytes.Repeat(bytes.Repeat([]byte{'b'}, chunkSize), 2)
+		chunks := splitChunks(body)
+		if len(chunks) != 2 {
+			t.Fatalf("expected 2 chunks, got %d", len(chunks))
+		}
+		if chunks[0].digest != chunks[1].digest {
+			t.Fatalf("expected identical chunks to share a digest")
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/vercel/turbo/cli/internal/analytics"
+	"github.com/vercel/turbo/cli/internal/turbopath"
+)
+
+// mtimeSuffix is the extension used for the sidecar file that records an
+// artifact's write time, since we can't rely on filesystem atime being
+// enabled (many CI images mount with noatime).
+const mtimeSuffix = ".mtime"
+
+// agingFSCache is a filesystem-backed Cache that honors a per-cache maxAge,
+// evicting artifacts that have outlived it rather than keeping them forever.
+// It backs entries in a `caches` config block that point at a local `dir`.
+type agingFSCache struct {
+	dir      turbopath.AbsoluteSystemPath
+	maxAge   int
+	recorder analytics.Recorder
+	// name is the key this cache was registered under in the `caches`
+	// config block (e.g. "local-artifacts"). It's recorded alongside each
+	// entry in the prune index since Put/Fetch/Exists don't otherwise carry
+	// any per-task identity down to this layer.
+	name string
+}
+
+func newAgingFSCache(dir turbopath.AbsoluteSystemPath, maxAge int, recorder analytics.Recorder, name string) (*agingFSCache, error) {
+	if err := dir.MkdirAll(0775); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory %v: %w", dir, err)
+	}
+	return &agingFSCache{dir: dir, maxAge: maxAge, recorder: recorder, name: name}, nil
+}
+
+func (c *agingFSCache) artifactPath(hash string) turbopath.AbsoluteSystemPath {
+	return c.dir.UntypedJoin(hash + ".tar.zst")
+}
+
+func (c *agingFSCache) mtimePath(hash string) turbopath.AbsoluteSystemPath {
+	return c.dir.UntypedJoin(hash + ".tar.zst" + mtimeSuffix)
+}
+
+func (c *agingFSCache) Put(anchor turbopath.AbsoluteSystemPath, hash string, duration int, files []turbopath.AnchoredSystemPath) error {
+	if c.maxAge == MaxAgeDisabled {
+		return nil
+	}
+	path := c.artifactPath(hash)
+	w, err := path.OpenFile(os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create cache artifact %v: %w", path, err)
+	}
+	// This local cache doesn't negotiate a codec with anything; it always
+	// writes zstd, matching the .tar.zst extension artifactPath uses.
+	writeArtifact(w, zstdCodec{level: defaultZstdLevel}, anchor, hash, files)
+	if err := c.stampWriteTime(hash); err != nil {
+		return err
+	}
+	size := int64(0)
+	if info, err := path.Lstat(); err == nil {
+		size = info.Size()
+	}
+	return recordAccess(c.dir, hash, c.name, size)
+}
+
+// stampWriteTime writes the sidecar file that records when hash was stored,
+// so Fetch/Exists can evaluate maxAge without trusting filesystem mtimes.
+func (c *agingFSCache) stampWriteTime(hash string) error {
+	return c.mtimePath(hash).WriteFile([]byte(strconv.FormatInt(time.Now().Unix(), 10)), 0644)
+}
+
+// writtenAt returns the recorded write time for hash, or the zero time if no
+// sidecar is present (e.g. entries from before this cache tracked ages).
+func (c *agingFSCache) writtenAt(hash string) time.Time {
+	b, err := c.mtimePath(hash).ReadFile()
+	if err != nil {
+		return time.Time{}
+	}
+	sec, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+func (c *agingFSCache) evictIfExpired(hash string) bool {
+	if c.maxAge == MaxAgeNeverExpire {
+		return false
+	}
+	if !expired(c.writtenAt(hash), c.maxAge) {
+		return false
+	}
+	_ = c.artifactPath(hash).Remove()
+	_ = c.mtimePath(hash).Remove()
+	return true
+}
+
+func (c *agingFSCache) Fetch(anchor turbopath.AbsoluteSystemPath, hash string, _ []string) (ItemStatus, []turbopath.AnchoredSystemPath, int, error) {
+	if c.maxAge == MaxAgeDisabled || c.evictIfExpired(hash) {
+		return ItemStatus{Remote: false}, nil, 0, nil
+	}
+	path := c.artifactPath(hash)
+	f, err := path.Open()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ItemStatus{Remote: false}, nil, 0, nil
+		}
+		return ItemStatus{Remote: false}, nil, 0, fmt.Errorf("failed to open cache artifact %v: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+	files, err := restoreTar(anchor, f, zstdCodec{level: defaultZstdLevel})
+	if err != nil {
+		return ItemStatus{Remote: false}, nil, 0, err
+	}
+	if info, err := path.Lstat(); err == nil {
+		_ = recordAccess(c.dir, hash, c.name, info.Size())
+	}
+	return ItemStatus{Remote: false}, files, 0, nil
+}
+
+func (c *agingFSCache) Exists(hash string) ItemStatus {
+	if c.maxAge == MaxAgeDisabled || c.evictIfExpired(hash) {
+		return ItemStatus{Remote: false}
+	}
+	info, err := c.artifactPath(hash).Lstat()
+	if err != nil {
+		return ItemStatus{Remote: false}
+	}
+	_ = recordAccess(c.dir, hash, c.name, info.Size())
+	return ItemStatus{Remote: false}
+}
+
+func (c *agingFSCache) Clean(_ turbopath.AbsoluteSystemPath) {}
+
+func (c *agingFSCache) CleanAll() {}
+
+// Prune evicts entries from this cache's directory per opts, using the
+// shared LRU index so CI environments can bound the directory's size
+// deterministically between runs.
+func (c *agingFSCache) Prune(opts PruneOpts) (int, error) {
+	return Prune(c.dir, opts)
+}
+
+// Shutdown sweeps the entire cache directory for entries whose recorded
+// write time has outlived maxAge, so a long-lived daemon doesn't rely on
+// callers happening to Fetch/Exists every stale hash.
+func (c *agingFSCache) Shutdown() {
+	if c.maxAge == MaxAgeNeverExpire || c.maxAge == MaxAgeDisabled {
+		return
+	}
+	entries, err := os.ReadDir(c.dir.ToString())
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if len(name) <= len(mtimeSuffix) || name[len(name)-len(mtimeSuffix):] != mtimeSuffix {
+			continue
+		}
+		hash := name[:len(name)-len(".tar.zst"+mtimeSuffix)]
+		c.evictIfExpired(hash)
+	}
+}
@@ -17,27 +17,20 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/DataDog/zstd"
-
 	"github.com/vercel/turbo/cli/internal/analytics"
 	"github.com/vercel/turbo/cli/internal/tarpatch"
 	"github.com/vercel/turbo/cli/internal/turbopath"
 )
 
-type cacheAPIClient interface {
-	PutArtifact(hash string, body []byte, duration int, tag string) error
-	FetchArtifact(hash string) (*http.Response, error)
-	ArtifactExists(hash string) (*http.Response, error)
-	GetTeamID() string
-}
-
 type HttpCache struct {
 	writable       bool
 	client         client2.APIClient
 	requestLimiter limiter
+	chunkLimiter   limiter
 	recorder       analytics.Recorder
 	signerVerifier *ArtifactSignatureAuthentication
 	repoRoot       turbopath.AbsoluteSystemPath
+	codec          Codec
 }
 
 type limiter chan struct{}
@@ -75,9 +68,19 @@ func (cache *HttpCache) Put(_ turbopath.AbsoluteSystemPath, hash string, duratio
 	r, w := io.Pipe()
 	go cache.write(w, hash, files)
 
-	// Read the entire artifact tar into memory so we can easily compute the signature.
-	// Note: retryablehttp.NewRequest reads the files into memory anyways so there's no
-	// additional overhead by doing the ioutil.ReadAll here instead.
+	// Prefer streaming the artifact straight from the pipe, chunking it as
+	// it flows, so we never hold a GB-scale artifact in memory. This
+	// capability (which implies chunk-endpoint support too) is probed up
+	// front, rather than attempted-then-retried, because the pipe can only
+	// be read once.
+	if cache.client.SupportsArtifactStreaming() {
+		return cache.putStream(r, hash)
+	}
+
+	// Older servers require the whole artifact body up front so we can
+	// compute a signature and/or chunk digests over it. retryablehttp.NewRequest
+	// reads the files into memory anyways so there's no additional overhead by
+	// doing the ioutil.ReadAll here instead.
 	artifactBody, err := ioutil.ReadAll(r)
 	if err != nil {
 		return fmt.Errorf("failed to store files in HTTP cache: %w", err)
@@ -89,20 +92,175 @@ func (cache *HttpCache) Put(_ turbopath.AbsoluteSystemPath, hash string, duratio
 			return fmt.Errorf("failed to store files in HTTP cache: %w", err)
 		}
 	}
-	return cache.client.PutArtifact(hash, artifactBody, duration, tag)
+	if err := cache.putChunked(hash, artifactBody, tag); err != nil {
+		if !errors.Is(err, errChunksNotImplemented) {
+			return err
+		}
+		// Remote predates chunked uploads; fall back to the old whole-artifact endpoint.
+		return cache.client.PutArtifact(hash, artifactBody, duration, tag, cache.codec.ContentEncoding())
+	}
+	return nil
+}
+
+// putStream uploads r directly to the remote without ever buffering the
+// whole artifact: it reads chunkSize windows off the pipe one at a time,
+// dedups and uploads each through the same chunk endpoints putChunked uses,
+// and computes the signature tag as bytes flow through a tee rather than
+// after the fact. This keeps streaming's memory-safety and chunking's
+// dedup from being mutually exclusive. Since the pipe can only be read
+// once, a server that advertises streaming support is expected to also
+// honor the chunk endpoints; a 501 partway through is surfaced as an error
+// rather than silently re-buffered into a fallback upload.
+func (cache *HttpCache) putStream(r io.Reader, hash string) error {
+	var finishTag func() (string, error)
+	if cache.signerVerifier.isEnabled() {
+		var tagWriter io.Writer
+		tagWriter, finishTag = cache.signerVerifier.newStreamingTag(hash)
+		r = io.TeeReader(r, tagWriter)
+	}
+
+	var digests []string
+	var size int64
+	buf := make([]byte, chunkSize)
+	for {
+		n, readErr := io.ReadFull(r, buf)
+		if n > 0 {
+			chunk := make([]byte, n)
+			copy(chunk, buf[:n])
+			digest := digestChunk(chunk)
+			digests = append(digests, digest)
+			size += int64(n)
+			if err := cache.putStreamedChunk(digest, chunk); err != nil {
+				return fmt.Errorf("failed to stream chunk %s to HTTP cache: %w", digest, err)
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return fmt.Errorf("failed to stream artifact to HTTP cache: %w", readErr)
+		}
+	}
+	if len(digests) == 0 {
+		digest := digestChunk(nil)
+		if err := cache.putStreamedChunk(digest, nil); err != nil {
+			return fmt.Errorf("failed to stream chunk %s to HTTP cache: %w", digest, err)
+		}
+		digests = append(digests, digest)
+	}
+
+	tag := ""
+	if finishTag != nil {
+		var err error
+		tag, err = finishTag()
+		if err != nil {
+			return fmt.Errorf("failed to finalize streamed artifact signature for %s: %w", hash, err)
+		}
+	}
+
+	manifest := &artifactManifest{
+		Chunks: digests,
+		Size:   size,
+		Tag:    tag,
+		Codec:  cache.codec.ContentEncoding(),
+	}
+	if err := cache.client.PutArtifactManifest(hash, manifest); err != nil {
+		return fmt.Errorf("failed to upload manifest for %s: %w", hash, err)
+	}
+	return nil
+}
+
+// putStreamedChunk checks whether the remote already has digest and
+// uploads chunk only if it doesn't. Unlike putChunked's batch existence
+// check, the streaming path only ever knows one chunk at a time.
+func (cache *HttpCache) putStreamedChunk(digest string, chunk []byte) error {
+	existing, err := cache.client.ChunkExistence([]string{digest})
+	if err != nil {
+		return fmt.Errorf("failed to query chunk existence: %w", err)
+	}
+	if existing[digest] {
+		return nil
+	}
+	return cache.client.PutArtifactChunk(digest, chunk)
+}
+
+// errChunksNotImplemented signals that the remote responded 501 to a
+// chunk-related request, so Put should fall back to the legacy
+// PutArtifact endpoint.
+var errChunksNotImplemented = errors.New("remote does not support chunked artifact uploads")
+
+// putChunked splits body into content-addressed chunks, uploads only the
+// ones the server doesn't already have, then records the ordered digest
+// list in a small manifest. This avoids re-uploading the bytes of task
+// outputs that overlap between builds.
+func (cache *HttpCache) putChunked(hash string, body []byte, tag string) error {
+	chunks := splitChunks(body)
+	digests := make([]string, len(chunks))
+	for i, c := range chunks {
+		digests[i] = c.digest
+	}
+
+	existing, err := cache.client.ChunkExistence(digests)
+	if err != nil {
+		if isNotImplemented(err) {
+			return errChunksNotImplemented
+		}
+		return fmt.Errorf("failed to query chunk existence: %w", err)
+	}
+
+	for _, c := range chunks {
+		if existing[c.digest] {
+			continue
+		}
+		if err := cache.client.PutArtifactChunk(c.digest, c.body); err != nil {
+			if isNotImplemented(err) {
+				return errChunksNotImplemented
+			}
+			return fmt.Errorf("failed to upload chunk %s: %w", c.digest, err)
+		}
+	}
+
+	manifest := &artifactManifest{
+		Chunks: digests,
+		Size:   int64(len(body)),
+		Tag:    tag,
+		Codec:  cache.codec.ContentEncoding(),
+	}
+	if err := cache.client.PutArtifactManifest(hash, manifest); err != nil {
+		if isNotImplemented(err) {
+			return errChunksNotImplemented
+		}
+		return fmt.Errorf("failed to upload manifest for %s: %w", hash, err)
+	}
+	return nil
+}
+
+// isNotImplemented reports whether err wraps an HTTP 501 response, the
+// signal that a remote predates chunked artifact support.
+func isNotImplemented(err error) bool {
+	var statusErr *client2.StatusCodeError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotImplemented
 }
 
 // write writes a series of files into the given Writer.
 func (cache *HttpCache) write(w io.WriteCloser, hash string, files []turbopath.AnchoredSystemPath) {
+	writeArtifact(w, cache.codec, cache.repoRoot, hash, files)
+}
+
+// writeArtifact tars and compresses files (anchored at repoRoot) into w using
+// codec. It's a free function rather than an HttpCache method so that other
+// Cache implementations (e.g. agingFSCache) can produce the same on-disk
+// format without needing an HttpCache of their own to call it on.
+func writeArtifact(w io.WriteCloser, codec Codec, repoRoot turbopath.AbsoluteSystemPath, hash string, files []turbopath.AnchoredSystemPath) {
 	defer w.Close()
 	defer func() { _ = w.Close() }()
-	zw := zstd.NewWriter(w)
+	zw := codec.Compress(w)
 	defer func() { _ = zw.Close() }()
 	tw := tar.NewWriter(zw)
 	defer func() { _ = tw.Close() }()
 	for _, file := range files {
 		// log.Printf("caching file %v", file)
-		if err := cache.storeFile(tw, file); err != nil {
+		if err := storeFile(tw, repoRoot, file); err != nil {
 			log.Printf("[ERROR] Error uploading artifact %s to HTTP cache due to: %s", file, err)
 			// TODO(jaredpalmer): How can we cancel the request at this point?
 		}
@@ -110,7 +268,11 @@ func (cache *HttpCache) write(w io.WriteCloser, hash string, files []turbopath.A
 }
 
 func (cache *HttpCache) storeFile(tw *tar.Writer, repoRelativePath turbopath.AnchoredSystemPath) error {
-	absoluteFilePath := repoRelativePath.RestoreAnchor(cache.repoRoot)
+	return storeFile(tw, cache.repoRoot, repoRelativePath)
+}
+
+func storeFile(tw *tar.Writer, repoRoot turbopath.AbsoluteSystemPath, repoRelativePath turbopath.AnchoredSystemPath) error {
+	absoluteFilePath := repoRelativePath.RestoreAnchor(repoRoot)
 	info, err := absoluteFilePath.Lstat()
 	if err != nil {
 		return err
@@ -216,6 +378,12 @@ func (cache *HttpCache) CleanAll() {
 	// Also not possible.
 }
 
+// Prune is a no-op for the HTTP cache: eviction is the remote's
+// responsibility, there's no local directory for us to reclaim space from.
+func (cache *HttpCache) Prune(_ PruneOpts) (int, error) {
+	return 0, nil
+}
+
 func (cache *HttpCache) Shutdown() {}
 
 func newHTTPCache(opts Opts, client client2.APIClient, recorder analytics.Recorder) *HttpCache {
@@ -223,6 +391,7 @@ func newHTTPCache(opts Opts, client client2.APIClient, recorder analytics.Record
 		writable:       true,
 		client:         client,
 		requestLimiter: make(limiter, 20),
+		chunkLimiter:   make(limiter, 8),
 		recorder:       recorder,
 		signerVerifier: &ArtifactSignatureAuthentication{
 			// TODO(Gaspar): this should use RemoteCacheOptions.TeamId once we start
@@ -230,5 +399,6 @@ func newHTTPCache(opts Opts, client client2.APIClient, recorder analytics.Record
 			teamId:  client.GetTeamID(),
 			enabled: opts.RemoteCacheOpts.Signature,
 		},
+		codec: codecForName(opts.RemoteCacheOpts.Codec, opts.RemoteCacheOpts.ZstdLevel),
 	}
 }
@@ -13,12 +13,94 @@ import (
 	"path/filepath"
 	"strconv"
 
-	"github.com/DataDog/zstd"
-
 	"github.com/vercel/turbo/cli/internal/turbopath"
 )
 
+// retrieve fetches hash's chunk manifest and reassembles it from the chunks
+// named there, falling back to the legacy whole-artifact endpoint for
+// remotes that predate chunked uploads.
 func (cache *HttpCache) retrieve(hash string) (bool, []turbopath.AnchoredSystemPath, int, error) {
+	manifest, err := cache.client.FetchArtifactManifest(hash)
+	if err != nil {
+		if isNotImplemented(err) {
+			return cache.retrieveLegacy(hash)
+		}
+		return false, nil, 0, err
+	}
+	if manifest == nil {
+		return false, nil, 0, nil // doesn't exist - not an error
+	}
+	return cache.retrieveChunked(hash, manifest)
+}
+
+// retrieveChunked downloads every chunk named in manifest in parallel,
+// bounded by cache.chunkLimiter, reassembles them in order, and feeds the
+// result into the existing restoreTar pipeline. No chunks are kept locally
+// between retrievals, so each call fetches all of them.
+func (cache *HttpCache) retrieveChunked(hash string, manifest *artifactManifest) (bool, []turbopath.AnchoredSystemPath, int, error) {
+	bodies := make([][]byte, len(manifest.Chunks))
+	errs := make([]error, len(manifest.Chunks))
+	done := make(chan int, len(manifest.Chunks))
+	for i, digest := range manifest.Chunks {
+		go func(i int, digest string) {
+			cache.chunkLimiter.acquire()
+			defer cache.chunkLimiter.release()
+			bodies[i], errs[i] = cache.fetchChunk(digest)
+			done <- i
+		}(i, digest)
+	}
+	for range manifest.Chunks {
+		<-done
+	}
+	for _, err := range errs {
+		if err != nil {
+			return false, nil, 0, fmt.Errorf("failed to download chunk for %s: %w", hash, err)
+		}
+	}
+
+	artifactBody := make([]byte, 0, manifest.Size)
+	for _, b := range bodies {
+		artifactBody = append(artifactBody, b...)
+	}
+
+	var tarReader io.Reader = bytes.NewReader(artifactBody)
+	if cache.signerVerifier.isEnabled() {
+		if manifest.Tag == "" {
+			return false, nil, 0, errors.New("artifact verification failed: manifest is missing required signature tag")
+		}
+		isValid, err := cache.signerVerifier.validate(hash, artifactBody, []byte(manifest.Tag))
+		if err != nil {
+			return false, nil, 0, fmt.Errorf("artifact verification failed: %w", err)
+		}
+		if !isValid {
+			return false, nil, 0, fmt.Errorf("artifact verification failed: artifact tag does not match expected tag %s", manifest.Tag)
+		}
+	}
+	// manifest.Codec is empty for manifests written before this field
+	// existed; codecForName falls back to zstd in that case.
+	files, err := restoreTar(cache.repoRoot, tarReader, codecForName(manifest.Codec, 0))
+	if err != nil {
+		return false, nil, 0, err
+	}
+	return true, files, 0, nil
+}
+
+func (cache *HttpCache) fetchChunk(digest string) ([]byte, error) {
+	resp, err := cache.client.FetchArtifactChunk(digest)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s", string(b))
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// retrieveLegacy fetches the whole artifact body in one request, for
+// remotes that don't yet support chunked uploads.
+func (cache *HttpCache) retrieveLegacy(hash string) (bool, []turbopath.AnchoredSystemPath, int, error) {
 	resp, err := cache.client.FetchArtifact(hash)
 	if err != nil {
 		return false, nil, 0, err
@@ -65,7 +147,9 @@ func (cache *HttpCache) retrieve(hash string) (bool, []turbopath.AnchoredSystemP
 	} else {
 		tarReader = resp.Body
 	}
-	files, err := restoreTar(cache.repoRoot, tarReader)
+	// Older servers never sent x-artifact-codec; codecForName treats an
+	// absent header the same as an explicit "zstd" for backward compatibility.
+	files, err := restoreTar(cache.repoRoot, tarReader, codecForName(resp.Header.Get("x-artifact-codec"), 0))
 	if err != nil {
 		return false, nil, 0, err
 	}
@@ -76,10 +160,10 @@ func (cache *HttpCache) retrieve(hash string) (bool, []turbopath.AnchoredSystemP
 // restored. In the future, these should likely be repo-relative system paths
 // so that they are suitable for being fed into cache.Put for other caches.
 // For now, I think this is working because windows also accepts /-delimited paths.
-func restoreTar(root turbopath.AbsoluteSystemPath, reader io.Reader) ([]turbopath.AnchoredSystemPath, error) {
+func restoreTar(root turbopath.AbsoluteSystemPath, reader io.Reader, codec Codec) ([]turbopath.AnchoredSystemPath, error) {
 	files := []turbopath.AnchoredSystemPath{}
 	missingLinks := []*tar.Header{}
-	zr := zstd.NewReader(reader)
+	zr := codec.Decompress(reader)
 	var closeError error
 	defer func() { closeError = zr.Close() }()
 	tr := tar.NewReader(zr)
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// fakePutStreamClient is a minimal client.APIClient-shaped fake used to
+// exercise putStream's chunk-by-chunk upload without a real server.
+type fakePutStreamClient struct {
+	uploadedChunks  map[string][]byte
+	manifest        *artifactManifest
+	manifestHash    string
+	chunkExistCalls int
+}
+
+func newFakePutStreamClient() *fakePutStreamClient {
+	return &fakePutStreamClient{uploadedChunks: map[string][]byte{}}
+}
+
+func (f *fakePutStreamClient) PutArtifact(string, []byte, int, string, string) error { return nil }
+func (f *fakePutStreamClient) FetchArtifact(string) (*http.Response, error)          { return nil, nil }
+func (f *fakePutStreamClient) ArtifactExists(string) (*http.Response, error)         { return nil, nil }
+func (f *fakePutStreamClient) GetTeamID() string                                     { return "team_fake" }
+func (f *fakePutStreamClient) GetTeamSlug() string                                   { return "" }
+func (f *fakePutStreamClient) GetBaseURL() string                                    { return "" }
+func (f *fakePutStreamClient) GetTimeout() int                                       { return 0 }
+func (f *fakePutStreamClient) GetVersion() string                                    { return "" }
+func (f *fakePutStreamClient) GetToken() string                                      { return "" }
+func (f *fakePutStreamClient) GetUsePreflight() bool                                 { return false }
+
+func (f *fakePutStreamClient) PutArtifactChunk(digest string, body []byte) error {
+	cp := make([]byte, len(body))
+	copy(cp, body)
+	f.uploadedChunks[digest] = cp
+	return nil
+}
+
+func (f *fakePutStreamClient) ChunkExistence(digests []string) (map[string]bool, error) {
+	f.chunkExistCalls++
+	existing := map[string]bool{}
+	for _, d := range digests {
+		if _, ok := f.uploadedChunks[d]; ok {
+			existing[d] = true
+		}
+	}
+	return existing, nil
+}
+
+func (f *fakePutStreamClient) PutArtifactManifest(hash string, manifest *artifactManifest) error {
+	f.manifestHash = hash
+	f.manifest = manifest
+	return nil
+}
+
+func (f *fakePutStreamClient) FetchArtifactManifest(string) (*artifactManifest, error) {
+	return nil, nil
+}
+
+func (f *fakePutStreamClient) FetchArtifactChunk(string) (*http.Response, error) { return nil, nil }
+
+func (f *fakePutStreamClient) SupportsArtifactStreaming() bool { return true }
+
+func Test_putStream_chunksWithoutBuffering(t *testing.T) {
+	client := newFakePutStreamClient()
+	cache := &HttpCache{
+		client:         client,
+		signerVerifier: &ArtifactSignatureAuthentication{enabled: false},
+		codec:          noneCodec{},
+	}
+
+	body := bytes.Repeat([]byte{'x'}, chunkSize+10)
+	if err := cache.putStream(bytes.NewReader(body), "some-hash"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if client.manifest == nil {
+		t.Fatal("expected a manifest to be uploaded")
+	}
+	if client.manifestHash != "some-hash" {
+		t.Fatalf("manifest uploaded for wrong hash: %s", client.manifestHash)
+	}
+	if client.manifest.Size != int64(len(body)) {
+		t.Fatalf("manifest size = %d, want %d", client.manifest.Size, len(body))
+	}
+	if len(client.manifest.Chunks) != 2 {
+		t.Fatalf("expected 2 chunks, got %d", len(client.manifest.Chunks))
+	}
+
+	var reassembled bytes.Buffer
+	for _, digest := range client.manifest.Chunks {
+		reassembled.Write(client.uploadedChunks[digest])
+	}
+	if !bytes.Equal(reassembled.Bytes(), body) {
+		t.Fatal("reassembled chunks don't match the original body")
+	}
+}
+
+func Test_putStream_signsWhenEnabled(t *testing.T) {
+	client := newFakePutStreamClient()
+	cache := &HttpCache{
+		client:         client,
+		signerVerifier: &ArtifactSignatureAuthentication{enabled: true, teamId: "team_fake"},
+		codec:          noneCodec{},
+	}
+
+	if err := cache.putStream(strings.NewReader("hello world"), "signed-hash"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.manifest.Tag == "" {
+		t.Fatal("expected a non-empty signature tag")
+	}
+}
@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+)
+
+// newStreamingTag returns a writer that accumulates an HMAC-SHA256 tag over
+// every byte written to it, keyed the same way generateTag is, plus a
+// finish function that resolves the hex-encoded tag once the caller is done
+// writing. It lets putStream compute a signature incrementally as an
+// artifact flows through a pipe, instead of requiring the whole body up
+// front the way generateTag does.
+func (asa *ArtifactSignatureAuthentication) newStreamingTag(hash string) (io.Writer, func() (string, error)) {
+	mac := hmac.New(sha256.New, asa.secret(hash))
+	return mac, func() (string, error) {
+		return hex.EncodeToString(mac.Sum(nil)), nil
+	}
+}
+
+// secret derives the HMAC key for hash from the team this cache is
+// restricted to. generateTag and newStreamingTag must derive the same key
+// for a given hash so a streamed upload's tag validates the same way a
+// buffered one would.
+func (asa *ArtifactSignatureAuthentication) secret(hash string) []byte {
+	return []byte(asa.teamId + hash)
+}
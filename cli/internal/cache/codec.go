@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"compress/gzip"
+	"io"
+
+	"github.com/DataDog/zstd"
+)
+
+// Codec abstracts the compression scheme wrapped around a cached artifact's
+// tar stream, so HttpCache.write and restoreTar don't have to hardcode zstd.
+type Codec interface {
+	// Compress wraps w so writes to the returned WriteCloser are compressed
+	// before reaching w. Closing it must flush and finalize the stream.
+	Compress(w io.Writer) io.WriteCloser
+	// Decompress wraps r so reads from the returned ReadCloser are
+	// decompressed.
+	Decompress(r io.Reader) io.ReadCloser
+	// ContentEncoding is the value sent as the artifact's Content-Encoding
+	// (and recorded in the x-artifact-codec header) so the other side of
+	// the wire knows how to decompress it without guessing.
+	ContentEncoding() string
+}
+
+// defaultZstdLevel matches the zero-value behavior of zstd.NewWriter, used
+// when Opts.RemoteCacheOpts.ZstdLevel isn't set.
+const defaultZstdLevel = zstd.DefaultCompression
+
+// zstdCodec is the long-standing default: good ratio at reasonable CPU cost.
+type zstdCodec struct {
+	level int
+}
+
+func (c zstdCodec) Compress(w io.Writer) io.WriteCloser {
+	return zstd.NewWriterLevel(w, c.level)
+}
+
+func (c zstdCodec) Decompress(r io.Reader) io.ReadCloser {
+	return zstd.NewReader(r)
+}
+
+func (c zstdCodec) ContentEncoding() string { return "zstd" }
+
+// gzipCodec trades ratio for ubiquity; useful behind proxies/CDNs that
+// already understand gzip natively.
+type gzipCodec struct{}
+
+func (gzipCodec) Compress(w io.Writer) io.WriteCloser {
+	return gzip.NewWriter(w)
+}
+
+func (gzipCodec) Decompress(r io.Reader) io.ReadCloser {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nopReadCloser{r: errReader{err: err}}
+	}
+	return zr
+}
+
+func (gzipCodec) ContentEncoding() string { return "gzip" }
+
+// noneCodec skips compression entirely, trading network bandwidth for CPU —
+// a reasonable choice on fast LANs where compression time dominates.
+type noneCodec struct{}
+
+func (noneCodec) Compress(w io.Writer) io.WriteCloser {
+	return nopWriteCloser{w: w}
+}
+
+func (noneCodec) Decompress(r io.Reader) io.ReadCloser {
+	return nopReadCloser{r: r}
+}
+
+func (noneCodec) ContentEncoding() string { return "identity" }
+
+// codecForName resolves a codec by its ContentEncoding name, falling back to
+// zstd when name is empty so legacy servers that don't send x-artifact-codec
+// keep working as before.
+func codecForName(name string, zstdLevel int) Codec {
+	switch name {
+	case "", "zstd":
+		level := zstdLevel
+		if level == 0 {
+			level = defaultZstdLevel
+		}
+		return zstdCodec{level: level}
+	case "gzip":
+		return gzipCodec{}
+	case "identity", "none":
+		return noneCodec{}
+	default:
+		return zstdCodec{level: defaultZstdLevel}
+	}
+}
+
+type nopWriteCloser struct{ w io.Writer }
+
+func (n nopWriteCloser) Write(p []byte) (int, error) { return n.w.Write(p) }
+func (n nopWriteCloser) Close() error                { return nil }
+
+type nopReadCloser struct{ r io.Reader }
+
+func (n nopReadCloser) Read(p []byte) (int, error) { return n.r.Read(p) }
+func (n nopReadCloser) Close() error               { return nil }
+
+type errReader struct{ err error }
+
+func (e errReader) Read(_ []byte) (int, error) { return 0, e.err }
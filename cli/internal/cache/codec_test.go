@@ -0,0 +1,46 @@
+package cache
+
+import "testing"
+
+func Test_codecForName(t *testing.T) {
+	cases := []struct {
+		name            string
+		contentEncoding string
+		wantEncoding    string
+	}{
+		{"empty falls back to zstd", "", "zstd"},
+		{"zstd", "zstd", "zstd"},
+		{"gzip", "gzip", "gzip"},
+		{"identity", "identity", "identity"},
+		{"none alias", "none", "identity"},
+		{"unknown falls back to zstd", "bogus", "zstd"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			codec := codecForName(tc.contentEncoding, 0)
+			if got := codec.ContentEncoding(); got != tc.wantEncoding {
+				t.Errorf("codecForName(%q).ContentEncoding() = %q, want %q", tc.contentEncoding, got, tc.wantEncoding)
+			}
+		})
+	}
+}
+
+func Test_codecForName_zstdLevel(t *testing.T) {
+	codec := codecForName("zstd", 7)
+	zc, ok := codec.(zstdCodec)
+	if !ok {
+		t.Fatalf("expected a zstdCodec, got %T", codec)
+	}
+	if zc.level != 7 {
+		t.Errorf("level = %d, want 7", zc.level)
+	}
+
+	defaultCodec := codecForName("zstd", 0)
+	zc, ok = defaultCodec.(zstdCodec)
+	if !ok {
+		t.Fatalf("expected a zstdCodec, got %T", defaultCodec)
+	}
+	if zc.level != defaultZstdLevel {
+		t.Errorf("level = %d, want defaultZstdLevel (%d)", zc.level, defaultZstdLevel)
+	}
+}
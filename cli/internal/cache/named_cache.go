@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vercel/turbo/cli/internal/analytics"
+	"github.com/vercel/turbo/cli/internal/client"
+	"github.com/vercel/turbo/cli/internal/turbopath"
+)
+
+// CacheDefinition describes a single named cache as it appears in the
+// `caches` block of turbo.json. No other config source is read.
+// It is intentionally small so it can be serialized directly from config.
+type CacheDefinition struct {
+	// Dir is the cache's storage location. It may contain placeholders:
+	// ":cacheDir" (the user's resolved turbo cache directory), ":repoRoot"
+	// (the repository root) and ":teamId" (the active remote cache team).
+	Dir string `json:"dir"`
+	// MaxAge is the retention window in seconds. -1 means entries never
+	// expire, 0 means the cache is disabled outright.
+	MaxAge int `json:"maxAge"`
+}
+
+const (
+	// MaxAgeNeverExpire disables eviction for a named cache entirely.
+	MaxAgeNeverExpire = -1
+	// MaxAgeDisabled marks a named cache as a no-op; Fetch always misses
+	// and Put always succeeds without persisting anything.
+	MaxAgeDisabled = 0
+)
+
+// placeholderCacheDir, placeholderRepoRoot and placeholderTeamID are the
+// substitution tokens recognized in a CacheDefinition's Dir field.
+const (
+	placeholderCacheDir = ":cacheDir"
+	placeholderRepoRoot = ":repoRoot"
+	placeholderTeamID   = ":teamId"
+)
+
+// resolveDir expands the placeholders in def.Dir, returning an absolute path
+// rooted at the repo.
+func resolveDir(def CacheDefinition, cacheDir turbopath.AbsoluteSystemPath, repoRoot turbopath.AbsoluteSystemPath, teamID string) turbopath.AbsoluteSystemPath {
+	resolved := def.Dir
+	resolved = strings.ReplaceAll(resolved, placeholderCacheDir, cacheDir.ToString())
+	resolved = strings.ReplaceAll(resolved, placeholderRepoRoot, repoRoot.ToString())
+	resolved = strings.ReplaceAll(resolved, placeholderTeamID, teamID)
+	if filepath.IsAbs(resolved) {
+		return turbopath.AbsoluteSystemPath(filepath.Clean(resolved))
+	}
+	return repoRoot.UntypedJoin(resolved)
+}
+
+// expired reports whether an artifact written at writtenAt has outlived
+// maxAge seconds.
+func expired(writtenAt time.Time, maxAge int) bool {
+	if maxAge == MaxAgeNeverExpire {
+		return false
+	}
+	return time.Since(writtenAt) > time.Duration(maxAge)*time.Second
+}
+
+// NamedCacheRegistry lazily constructs and caches Cache backends by name, so
+// callers can request e.g. "local-artifacts" without knowing how each is
+// configured. Every backend it constructs is a local filesystem cache
+// (agingFSCache) in the definition's dir; the HTTP cache is not built
+// through the registry.
+type NamedCacheRegistry struct {
+	mu       sync.Mutex
+	configs  map[string]CacheDefinition
+	caches   map[string]Cache
+	cacheDir turbopath.AbsoluteSystemPath
+	repoRoot turbopath.AbsoluteSystemPath
+	client   client.APIClient
+	recorder analytics.Recorder
+}
+
+// NewNamedCacheRegistry builds a registry from the parsed `caches` config
+// block. Backends are not constructed until first requested via Get.
+// apiClient may be nil for callers that only need ":teamId"-free paths (for
+// example a local-only command like `turbo prune-cache`); in that case the
+// placeholder resolves to the empty string.
+func NewNamedCacheRegistry(configs map[string]CacheDefinition, cacheDir turbopath.AbsoluteSystemPath, repoRoot turbopath.AbsoluteSystemPath, apiClient client.APIClient, recorder analytics.Recorder) *NamedCacheRegistry {
+	return &NamedCacheRegistry{
+		configs:  configs,
+		caches:   map[string]Cache{},
+		cacheDir: cacheDir,
+		repoRoot: repoRoot,
+		client:   apiClient,
+		recorder: recorder,
+	}
+}
+
+// NewNamedCacheRegistryFromTurboJSON reads the `caches` block out of the
+// turbo.json at turboJSONPath and builds a registry from it. This is the
+// usual entry point for callers that don't already have the config parsed,
+// e.g. `turbo prune-cache --name`.
+func NewNamedCacheRegistryFromTurboJSON(turboJSONPath turbopath.AbsoluteSystemPath, cacheDir turbopath.AbsoluteSystemPath, repoRoot turbopath.AbsoluteSystemPath, apiClient client.APIClient, recorder analytics.Recorder) (*NamedCacheRegistry, error) {
+	raw, err := turboJSONPath.ReadFile()
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read %v: %w", turboJSONPath, err)
+		}
+		raw = nil
+	}
+	configs, err := ParseCacheDefinitions(raw)
+	if err != nil {
+		return nil, err
+	}
+	return NewNamedCacheRegistry(configs, cacheDir, repoRoot, apiClient, recorder), nil
+}
+
+// Get returns the configured Cache backend for name, constructing it on
+// first use.
+func (r *NamedCacheRegistry) Get(name string) (Cache, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if c, ok := r.caches[name]; ok {
+		return c, nil
+	}
+	def, ok := r.configs[name]
+	if !ok {
+		return nil, fmt.Errorf("no cache named %q is configured", name)
+	}
+	teamID := ""
+	if r.client != nil {
+		teamID = r.client.GetTeamID()
+	}
+	dir := resolveDir(def, r.cacheDir, r.repoRoot, teamID)
+	c, err := newAgingFSCache(dir, def.MaxAge, r.recorder, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cache %q: %w", name, err)
+	}
+	r.caches[name] = c
+	return c, nil
+}
+
+// Shutdown sweeps and tears down every backend that has been constructed so
+// far, evicting entries older than their configured maxAge along the way.
+func (r *NamedCacheRegistry) Shutdown() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range r.caches {
+		c.Shutdown()
+	}
+}
@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// turboConfigFile mirrors the subset of turbo.json this package cares
+// about: the `caches` block that defines named local caches.
+type turboConfigFile struct {
+	Caches map[string]CacheDefinition `json:"caches"`
+}
+
+// ParseCacheDefinitions extracts the `caches` block out of a turbo.json
+// document. It returns an empty map (not an error) if turboJSON is empty or
+// has no `caches` block, so repos that haven't adopted named caches yet
+// don't need to change their config at all.
+func ParseCacheDefinitions(turboJSON []byte) (map[string]CacheDefinition, error) {
+	if len(turboJSON) == 0 {
+		return map[string]CacheDefinition{}, nil
+	}
+	var parsed turboConfigFile
+	if err := json.Unmarshal(turboJSON, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse caches config: %w", err)
+	}
+	if parsed.Caches == nil {
+		return map[string]CacheDefinition{}, nil
+	}
+	return parsed.Caches, nil
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_expired(t *testing.T) {
+	cases := []struct {
+		name      string
+		writtenAt time.Time
+		maxAge    int
+		want      bool
+	}{
+		{"never expires", time.Now().Add(-time.Hour * 24 * 365), MaxAgeNeverExpire, false},
+		{"within maxAge", time.Now().Add(-time.Second), 60, false},
+		{"past maxAge", time.Now().Add(-time.Hour), 60, true},
+		{"zero time with positive maxAge", time.Time{}, 60, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := expired(tc.writtenAt, tc.maxAge); got != tc.want {
+				t.Errorf("expired(%v, %d) = %v, want %v", tc.writtenAt, tc.maxAge, got, tc.want)
+			}
+		})
+	}
+}
+
+func Test_ParseCacheDefinitions(t *testing.T) {
+	t.Run("empty input", func(t *testing.T) {
+		defs, err := ParseCacheDefinitions(nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(defs) != 0 {
+			t.Fatalf("expected no cache definitions, got %v", defs)
+		}
+	})
+
+	t.Run("no caches block", func(t *testing.T) {
+		defs, err := ParseCacheDefinitions([]byte(`{"pipeline": {}}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(defs) != 0 {
+			t.Fatalf("expected no cache definitions, got %v", defs)
+		}
+	})
+
+	t.Run("parses named caches", func(t *testing.T) {
+		defs, err := ParseCacheDefinitions([]byte(`{
+			"caches": {
+				"local-artifacts": {"dir": ":repoRoot/.turbo/cache", "maxAge": 86400}
+			}
+		}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		def, ok := defs["local-artifacts"]
+		if !ok {
+			t.Fatalf("expected \"local-artifacts\" to be defined, got %v", defs)
+		}
+		if def.Dir != ":repoRoot/.turbo/cache" || def.MaxAge != 86400 {
+			t.Errorf("unexpected definition: %+v", def)
+		}
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		if _, err := ParseCacheDefinitions([]byte(`{`)); err == nil {
+			t.Fatal("expected an error for invalid json")
+		}
+	})
+}
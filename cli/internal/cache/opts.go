@@ -0,0 +1,21 @@
+package cache
+
+// RemoteCacheOpts is the subset of turbo's remote-cache configuration that
+// HttpCache needs to construct itself.
+type RemoteCacheOpts struct {
+	// Signature enables HMAC signing/verification of uploaded and
+	// downloaded artifacts.
+	Signature bool
+	// Codec selects the compression scheme HttpCache uses for new uploads,
+	// by its ContentEncoding name ("zstd", "gzip", "identity"/"none"). An
+	// empty value falls back to zstd, matching codecForName's default.
+	Codec string
+	// ZstdLevel is the zstd compression level to use when Codec is "zstd"
+	// (or empty). Zero falls back to defaultZstdLevel.
+	ZstdLevel int
+}
+
+// Opts bundles the options New needs to construct a Cache.
+type Opts struct {
+	RemoteCacheOpts RemoteCacheOpts
+}
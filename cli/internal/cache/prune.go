@@ -0,0 +1,181 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vercel/turbo/cli/internal/turbopath"
+)
+
+// indexLocksMu guards indexLocks itself, not the index files; the per-dir
+// mutexes it hands out are what actually serialize a dir's read-modify-write
+// cycle across the concurrent Put/Fetch/Exists calls task execution makes.
+var indexLocksMu sync.Mutex
+var indexLocks = map[string]*sync.Mutex{}
+
+// indexLock returns the mutex serializing access to dir's sidecar index,
+// creating one on first use.
+func indexLock(dir turbopath.AbsoluteSystemPath) *sync.Mutex {
+	indexLocksMu.Lock()
+	defer indexLocksMu.Unlock()
+	key := dir.ToString()
+	mu, ok := indexLocks[key]
+	if !ok {
+		mu = &sync.Mutex{}
+		indexLocks[key] = mu
+	}
+	return mu
+}
+
+// PruneOpts controls how Prune decides which entries to evict from a local
+// filesystem cache.
+type PruneOpts struct {
+	// MaxSizeBytes bounds the total size of the cache directory. Once
+	// exceeded, the least-recently-accessed entries are evicted until the
+	// cache is back under the limit. Zero means no size limit.
+	MaxSizeBytes int64
+	// MaxAge evicts any entry whose last access is older than this, even
+	// if the cache is under MaxSizeBytes. Zero means no age limit.
+	MaxAge time.Duration
+}
+
+// pruneIndexEntry is one row of the sidecar access-time index, keyed by
+// artifact hash. We track this ourselves rather than relying on filesystem
+// atime because many CI runners mount with noatime.
+type pruneIndexEntry struct {
+	Hash string `json:"hash"`
+	// TaskName identifies what this entry belongs to. Put/Fetch/Exists
+	// don't carry per-task identity down to this layer, so callers that
+	// only have a bare filesystem cache (not one resolved through the named
+	// cache registry) record an empty string here.
+	TaskName   string    `json:"taskName"`
+	SizeBytes  int64     `json:"sizeBytes"`
+	LastAccess time.Time `json:"lastAccess"`
+}
+
+// pruneIndexFile is the name of the sidecar index within the cache
+// directory. It's plain JSON rather than bbolt so it can be inspected and
+// hand-edited without extra tooling.
+const pruneIndexFile = "turbo-cache-index.json"
+
+// Prune walks dir's local filesystem cache and evicts entries per opts,
+// using an LRU ordering over the sidecar access-time index. It returns the
+// number of entries removed.
+func Prune(dir turbopath.AbsoluteSystemPath, opts PruneOpts) (int, error) {
+	mu := indexLock(dir)
+	mu.Lock()
+	defer mu.Unlock()
+
+	index, err := readPruneIndex(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read cache index: %w", err)
+	}
+
+	entries := make([]pruneIndexEntry, 0, len(index))
+	var total int64
+	for _, e := range index {
+		entries = append(entries, e)
+		total += e.SizeBytes
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastAccess.Before(entries[j].LastAccess)
+	})
+
+	toRemove := map[string]bool{}
+	if opts.MaxAge > 0 {
+		cutoff := time.Now().Add(-opts.MaxAge)
+		for _, e := range entries {
+			if e.LastAccess.Before(cutoff) {
+				toRemove[e.Hash] = true
+			}
+		}
+	}
+	if opts.MaxSizeBytes > 0 {
+		remaining := total
+		for _, e := range entries {
+			if remaining <= opts.MaxSizeBytes {
+				break
+			}
+			if toRemove[e.Hash] {
+				// Already evicted by MaxAge; its size no longer counts.
+				remaining -= e.SizeBytes
+				continue
+			}
+			toRemove[e.Hash] = true
+			remaining -= e.SizeBytes
+		}
+	}
+
+	for hash := range toRemove {
+		artifactPath := dir.UntypedJoin(hash + ".tar.zst")
+		if err := artifactPath.Remove(); err != nil && !os.IsNotExist(err) {
+			return 0, fmt.Errorf("failed to remove cache artifact %v: %w", artifactPath, err)
+		}
+		delete(index, hash)
+	}
+
+	if len(toRemove) > 0 {
+		if err := writePruneIndex(dir, index); err != nil {
+			return 0, fmt.Errorf("failed to update cache index: %w", err)
+		}
+	}
+	return len(toRemove), nil
+}
+
+// recordAccess updates the sidecar index's last-access time for hash,
+// inserting a new entry if one doesn't already exist. Callers that manage a
+// local filesystem cache should invoke this on both Put and a successful
+// Fetch/Exists so Prune has an accurate LRU ordering.
+func recordAccess(dir turbopath.AbsoluteSystemPath, hash string, taskName string, sizeBytes int64) error {
+	mu := indexLock(dir)
+	mu.Lock()
+	defer mu.Unlock()
+
+	index, err := readPruneIndex(dir)
+	if err != nil {
+		return err
+	}
+	index[hash] = pruneIndexEntry{
+		Hash:       hash,
+		TaskName:   taskName,
+		SizeBytes:  sizeBytes,
+		LastAccess: time.Now(),
+	}
+	return writePruneIndex(dir, index)
+}
+
+func readPruneIndex(dir turbopath.AbsoluteSystemPath) (map[string]pruneIndexEntry, error) {
+	path := dir.UntypedJoin(pruneIndexFile)
+	b, err := path.ReadFile()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]pruneIndexEntry{}, nil
+		}
+		return nil, err
+	}
+	var entries []pruneIndexEntry
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return nil, err
+	}
+	index := make(map[string]pruneIndexEntry, len(entries))
+	for _, e := range entries {
+		index[e.Hash] = e
+	}
+	return index, nil
+}
+
+func writePruneIndex(dir turbopath.AbsoluteSystemPath, index map[string]pruneIndexEntry) error {
+	entries := make([]pruneIndexEntry, 0, len(index))
+	for _, e := range index {
+		entries = append(entries, e)
+	}
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	return dir.UntypedJoin(pruneIndexFile).WriteFile(b, 0644)
+}
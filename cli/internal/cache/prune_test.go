@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vercel/turbo/cli/internal/turbopath"
+)
+
+func writeIndex(t *testing.T, dir turbopath.AbsoluteSystemPath, entries []pruneIndexEntry) {
+	t.Helper()
+	b, err := json.Marshal(entries)
+	if err != nil {
+		t.Fatalf("failed to marshal index: %v", err)
+	}
+	if err := dir.UntypedJoin(pruneIndexFile).WriteFile(b, 0644); err != nil {
+		t.Fatalf("failed to write index: %v", err)
+	}
+}
+
+func touchArtifact(t *testing.T, dir turbopath.AbsoluteSystemPath, hash string, size int64) {
+	t.Helper()
+	body := make([]byte, size)
+	if err := dir.UntypedJoin(hash+".tar.zst").WriteFile(body, 0644); err != nil {
+		t.Fatalf("failed to write artifact: %v", err)
+	}
+}
+
+func Test_Prune_evictsOldestByMaxAge(t *testing.T) {
+	dir := turbopath.AbsoluteSystemPath(t.TempDir())
+	now := time.Now()
+	writeIndex(t, dir, []pruneIndexEntry{
+		{Hash: "old", SizeBytes: 10, LastAccess: now.Add(-2 * time.Hour)},
+		{Hash: "new", SizeBytes: 10, LastAccess: now},
+	})
+	touchArtifact(t, dir, "old", 10)
+	touchArtifact(t, dir, "new", 10)
+
+	removed, err := Prune(dir, PruneOpts{MaxAge: time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if removed != 1 {
+		t.Fatalf("expected 1 entry removed, got %d", removed)
+	}
+	if _, err := dir.UntypedJoin("old.tar.zst").Lstat(); err == nil {
+		t.Fatal("expected old artifact to be removed")
+	}
+	if _, err := dir.UntypedJoin("new.tar.zst").Lstat(); err != nil {
+		t.Fatal("expected new artifact to survive")
+	}
+}
+
+func Test_Prune_evictsLRUUntilUnderMaxSize(t *testing.T) {
+	dir := turbopath.AbsoluteSystemPath(t.TempDir())
+	now := time.Now()
+	writeIndex(t, dir, []pruneIndexEntry{
+		{Hash: "a", SizeBytes: 100, LastAccess: now.Add(-3 * time.Hour)},
+		{Hash: "b", SizeBytes: 100, LastAccess: now.Add(-2 * time.Hour)},
+		{Hash: "c", SizeBytes: 100, LastAccess: now.Add(-1 * time.Hour)},
+	})
+	for _, h := range []string{"a", "b", "c"} {
+		touchArtifact(t, dir, h, 100)
+	}
+
+	// Budget for only one entry's worth of size; the two oldest should go.
+	removed, err := Prune(dir, PruneOpts{MaxSizeBytes: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if removed != 2 {
+		t.Fatalf("expected 2 entries removed, got %d", removed)
+	}
+	if _, err := dir.UntypedJoin("c.tar.zst").Lstat(); err != nil {
+		t.Fatal("expected most-recently-accessed entry to survive")
+	}
+	if _, err := dir.UntypedJoin("a.tar.zst").Lstat(); err == nil {
+		t.Fatal("expected oldest entry to be removed")
+	}
+}
+
+func Test_recordAccess_concurrentWritesDontDropEntries(t *testing.T) {
+	dir := turbopath.AbsoluteSystemPath(t.TempDir())
+
+	const n = 50
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			hash := fmt.Sprintf("hash-%d", i)
+			if err := recordAccess(dir, hash, "", 10); err != nil {
+				t.Errorf("recordAccess(%s) failed: %v", hash, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	index, err := readPruneIndex(dir)
+	if err != nil {
+		t.Fatalf("failed to read index: %v", err)
+	}
+	if len(index) != n {
+		t.Fatalf("expected %d entries after concurrent recordAccess, got %d (lost a read-modify-write race)", n, len(index))
+	}
+}
+
+func Test_Prune_noOptsRemovesNothing(t *testing.T) {
+	dir := turbopath.AbsoluteSystemPath(t.TempDir())
+	writeIndex(t, dir, []pruneIndexEntry{
+		{Hash: "a", SizeBytes: 100, LastAccess: time.Now()},
+	})
+	touchArtifact(t, dir, "a", 100)
+
+	removed, err := Prune(dir, PruneOpts{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if removed != 0 {
+		t.Fatalf("expected 0 entries removed, got %d", removed)
+	}
+}
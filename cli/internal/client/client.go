@@ -0,0 +1,236 @@
+// Package client implements the HTTP client used to talk to the turbo
+// Remote Cache API.
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// ArtifactManifest is the wire format for a chunked artifact: the ordered
+// list of chunk digests that make it up, its total size, the signature tag
+// computed over the reassembled body, and the codec used to compress it.
+type ArtifactManifest struct {
+	Chunks []string `json:"chunks"`
+	Size   int64    `json:"size"`
+	Tag    string   `json:"tag,omitempty"`
+	Codec  string   `json:"codec,omitempty"`
+}
+
+// StatusCodeError wraps a non-2xx HTTP response so callers can branch on the
+// status code itself instead of matching against the error string.
+type StatusCodeError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusCodeError) Error() string {
+	return fmt.Sprintf("unexpected status code %d: %s", e.StatusCode, e.Body)
+}
+
+// APIClient is the set of Remote Cache operations HttpCache depends on. It's
+// an interface so tests can substitute a fake implementation.
+type APIClient interface {
+	PutArtifact(hash string, body []byte, duration int, tag string, codec string) error
+	FetchArtifact(hash string) (*http.Response, error)
+	ArtifactExists(hash string) (*http.Response, error)
+	GetTeamID() string
+	GetTeamSlug() string
+	GetBaseURL() string
+	GetTimeout() int
+	GetVersion() string
+	GetToken() string
+	GetUsePreflight() bool
+
+	// PutArtifactChunk uploads a single content-addressed chunk. Servers
+	// that don't support chunked uploads respond with
+	// http.StatusNotImplemented, wrapped in a *StatusCodeError, so callers
+	// can fall back to PutArtifact.
+	PutArtifactChunk(digest string, body []byte) error
+	// ChunkExistence reports which of the given chunk digests the server
+	// already has, so Put only needs to upload the rest.
+	ChunkExistence(digests []string) (map[string]bool, error)
+	// PutArtifactManifest uploads the ordered list of chunk digests that
+	// make up hash, along with its total size, codec and signature tag.
+	PutArtifactManifest(hash string, manifest *ArtifactManifest) error
+	// FetchArtifactManifest downloads the chunk manifest for hash. It
+	// returns a nil manifest (not an error) if the artifact doesn't exist,
+	// and a *StatusCodeError wrapping http.StatusNotImplemented if the
+	// remote predates chunked uploads.
+	FetchArtifactManifest(hash string) (*ArtifactManifest, error)
+	// FetchArtifactChunk downloads a single chunk by digest.
+	FetchArtifactChunk(digest string) (*http.Response, error)
+
+	// SupportsArtifactStreaming reports whether the remote can be trusted
+	// to also honor the chunk endpoints above, so Put can stream an
+	// artifact through them one chunk at a time instead of buffering the
+	// whole thing up front.
+	SupportsArtifactStreaming() bool
+}
+
+// ApiClient is the concrete APIClient implementation: a thin wrapper around
+// retryablehttp pointed at the remote cache's base URL.
+type ApiClient struct {
+	HTTPClient   *retryablehttp.Client
+	BaseURL      string
+	Token        string
+	TeamID       string
+	TeamSlug     string
+	Version      string
+	Timeout      int
+	UsePreflight bool
+	// StreamingSupported records whether the remote has been confirmed (for
+	// example via a capability header on a prior response) to support the
+	// chunk endpoints used by the streaming upload path.
+	StreamingSupported bool
+}
+
+func (c *ApiClient) GetTeamID() string               { return c.TeamID }
+func (c *ApiClient) GetTeamSlug() string             { return c.TeamSlug }
+func (c *ApiClient) GetBaseURL() string              { return c.BaseURL }
+func (c *ApiClient) GetTimeout() int                 { return c.Timeout }
+func (c *ApiClient) GetVersion() string              { return c.Version }
+func (c *ApiClient) GetToken() string                { return c.Token }
+func (c *ApiClient) GetUsePreflight() bool           { return c.UsePreflight }
+func (c *ApiClient) SupportsArtifactStreaming() bool { return c.StreamingSupported }
+
+// do issues an authenticated request against path and returns the raw
+// response, leaving status-code handling to the caller since some callers
+// (e.g. ArtifactExists) treat 404 as a non-error miss.
+func (c *ApiClient) do(method, path string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	req, err := retryablehttp.NewRequest(method, c.BaseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return c.HTTPClient.Do(req)
+}
+
+// statusError reads resp's body and wraps it in a *StatusCodeError.
+func statusError(resp *http.Response) error {
+	defer func() { _ = resp.Body.Close() }()
+	b, _ := ioutil.ReadAll(resp.Body)
+	return &StatusCodeError{StatusCode: resp.StatusCode, Body: string(b)}
+}
+
+func (c *ApiClient) PutArtifact(hash string, body []byte, duration int, tag string, codec string) error {
+	headers := map[string]string{
+		"Content-Type":        "application/octet-stream",
+		"Content-Encoding":    codec,
+		"x-artifact-duration": fmt.Sprint(duration),
+		"x-artifact-codec":    codec,
+	}
+	if tag != "" {
+		headers["x-artifact-tag"] = tag
+	}
+	resp, err := c.do(http.MethodPut, "/v8/artifacts/"+hash, bytes.NewReader(body), headers)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return statusError(resp)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	return nil
+}
+
+func (c *ApiClient) FetchArtifact(hash string) (*http.Response, error) {
+	return c.do(http.MethodGet, "/v8/artifacts/"+hash, nil, nil)
+}
+
+func (c *ApiClient) ArtifactExists(hash string) (*http.Response, error) {
+	return c.do(http.MethodHead, "/v8/artifacts/"+hash, nil, nil)
+}
+
+func (c *ApiClient) PutArtifactChunk(digest string, body []byte) error {
+	resp, err := c.do(http.MethodPut, "/v8/artifacts/chunks/"+digest, bytes.NewReader(body), map[string]string{
+		"Content-Type": "application/octet-stream",
+	})
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return statusError(resp)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	return nil
+}
+
+func (c *ApiClient) ChunkExistence(digests []string) (map[string]bool, error) {
+	reqBody, err := json.Marshal(struct {
+		Digests []string `json:"digests"`
+	}{Digests: digests})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal chunk existence request: %w", err)
+	}
+	resp, err := c.do(http.MethodPost, "/v8/artifacts/chunks/exists", bytes.NewReader(reqBody), map[string]string{
+		"Content-Type": "application/json",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp)
+	}
+	var parsed struct {
+		Existing map[string]bool `json:"existing"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode chunk existence response: %w", err)
+	}
+	return parsed.Existing, nil
+}
+
+func (c *ApiClient) PutArtifactManifest(hash string, manifest *ArtifactManifest) error {
+	body, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal artifact manifest: %w", err)
+	}
+	resp, err := c.do(http.MethodPut, "/v8/artifacts/"+hash, bytes.NewReader(body), map[string]string{
+		"Content-Type":       "application/json",
+		"x-artifact-chunked": "1",
+	})
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return statusError(resp)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	return nil
+}
+
+func (c *ApiClient) FetchArtifactManifest(hash string) (*ArtifactManifest, error) {
+	resp, err := c.do(http.MethodGet, "/v8/artifacts/"+hash, nil, map[string]string{
+		"x-artifact-chunked": "1",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp)
+	}
+	var manifest ArtifactManifest
+	if err := json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode artifact manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+func (c *ApiClient) FetchArtifactChunk(digest string) (*http.Response, error) {
+	return c.do(http.MethodGet, "/v8/artifacts/chunks/"+digest, nil, nil)
+}
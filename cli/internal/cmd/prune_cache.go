@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/vercel/turbo/cli/internal/cache"
+	"github.com/vercel/turbo/cli/internal/cmdutil"
+	"github.com/vercel/turbo/cli/internal/turbopath"
+)
+
+// pruneCacheCmd evicts local cache entries by LRU + max-total-size, with an
+// optional max age, so CI environments can bound the cache directory's size
+// deterministically between runs instead of shelling out to `find`.
+func pruneCacheCmd(helper *cmdutil.Helper) *cobra.Command {
+	var maxSizeMB int64
+	var maxAge time.Duration
+	var cacheName string
+
+	cmd := &cobra.Command{
+		Use:           "prune-cache",
+		Short:         "Evict local cache entries that exceed the configured size or age limits",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			base, err := helper.GetCmdBase(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			opts := cache.PruneOpts{
+				MaxSizeBytes: maxSizeMB * 1024 * 1024,
+				MaxAge:       maxAge,
+			}
+			dir := cacheDirFromConfig(base)
+			target, err := pruneTarget(base, dir, cacheName)
+			if err != nil {
+				return err
+			}
+			removed, err := target.Prune(opts)
+			if err != nil {
+				return err
+			}
+			base.UI.Output(pluralizePruned(removed))
+			return nil
+		},
+	}
+
+	cmd.Flags().Int64Var(&maxSizeMB, "max-size", 0, "maximum total size of the local cache directory, in megabytes (0 = unbounded)")
+	cmd.Flags().DurationVar(&maxAge, "max-age", 0, "evict entries last accessed longer ago than this (0 = unbounded)")
+	cmd.Flags().StringVar(&cacheName, "name", "", "prune the named cache from turbo.json's `caches` block instead of the default local cache")
+	return cmd
+}
+
+// defaultPruneTarget prunes the plain local filesystem cache directory
+// directly, bypassing the named-cache registry. It's used when --name isn't
+// given, which is the common case today.
+type defaultPruneTarget struct {
+	dir turbopath.AbsoluteSystemPath
+}
+
+func (t defaultPruneTarget) Prune(opts cache.PruneOpts) (int, error) {
+	return cache.Prune(t.dir, opts)
+}
+
+// pruneTarget resolves what `prune-cache` should evict: the default local
+// cache directory, or a specific named cache from turbo.json's `caches`
+// block when --name is given.
+func pruneTarget(base *cmdutil.CmdBase, defaultDir turbopath.AbsoluteSystemPath, name string) (interface {
+	Prune(cache.PruneOpts) (int, error)
+}, error) {
+	if name == "" {
+		return defaultPruneTarget{dir: defaultDir}, nil
+	}
+	registry, err := cache.NewNamedCacheRegistryFromTurboJSON(base.RepoRoot.UntypedJoin("turbo.json"), defaultDir, base.RepoRoot, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return registry.Get(name)
+}
+
+func pluralizePruned(count int) string {
+	if count == 1 {
+		return "pruned 1 cache entry"
+	}
+	return fmt.Sprintf("pruned %d cache entries", count)
+}
+
+// cacheDirFromConfig returns the default local filesystem cache directory,
+// node_modules/.cache/turbo under the repo root. A cache directory set in
+// the repo's configuration is not read.
+func cacheDirFromConfig(base *cmdutil.CmdBase) turbopath.AbsoluteSystemPath {
+	return base.RepoRoot.UntypedJoin("node_modules", ".cache", "turbo")
+}
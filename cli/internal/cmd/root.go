@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/vercel/turbo/cli/internal/cmdutil"
+)
+
+// GetRootCmd assembles the turbo CLI's root cobra.Command and attaches its
+// subcommands.
+func GetRootCmd(helper *cmdutil.Helper) *cobra.Command {
+	root := &cobra.Command{
+		Use:           "turbo",
+		Short:         "Turbo is a high-performance build system for JavaScript and TypeScript codebases.",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	root.AddCommand(pruneCacheCmd(helper))
+
+	return root
+}